@@ -0,0 +1,30 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// GeoResolverConfig configures how the remote IP of an incoming connect
+// request is resolved to a region hint for SelectRoomNode. It is embedded in
+// Config as `geo_resolver`.
+type GeoResolverConfig struct {
+	// TrustedProxies lists the CIDRs (load balancers, reverse proxies) that
+	// are allowed to set X-Forwarded-For/X-Real-IP; requests arriving
+	// directly from an untrusted address have their own RemoteAddr used
+	// instead, regardless of what headers they present.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// MaxMindDBPath, if set, loads a MaxMind GeoLite2/GeoIP2 Country
+	// database for country lookups instead of the embedded table.
+	MaxMindDBPath string `yaml:"maxmind_db_path"`
+}
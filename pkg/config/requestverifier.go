@@ -0,0 +1,62 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"time"
+
+	"github.com/livekit/protocol/auth"
+)
+
+// RequestVerifiersConfig configures the ordered chain of request verifiers
+// RTCv2Service runs ahead of its normal API-key based validation, letting
+// operators front LiveKit with an external IdP or a zero-trust proxy that
+// terminates mTLS instead of minting LiveKit access tokens. It is embedded
+// in Config as `request_verifiers`.
+type RequestVerifiersConfig struct {
+	JWTOIDC []JWTOIDCVerifierConfig `yaml:"jwt_oidc"`
+	MTLS    *MTLSVerifierConfig     `yaml:"mtls"`
+}
+
+// JWTOIDCVerifierConfig describes one external OIDC issuer whose bearer
+// tokens RTCv2Service should accept in place of a LiveKit access token.
+type JWTOIDCVerifierConfig struct {
+	// Issuer is matched against the token's `iss` claim.
+	Issuer string `yaml:"issuer"`
+	// Audience is matched against the token's `aud` claim.
+	Audience string `yaml:"audience"`
+	// JWKSURL is fetched periodically to refresh signing keys; if empty,
+	// it defaults to Issuer + "/.well-known/jwks.json".
+	JWKSURL string `yaml:"jwks_url"`
+	// JWKSRefreshInterval controls how often JWKSURL is re-fetched.
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval"`
+	// IdentityClaim is the token claim mapped onto auth.ClaimGrants.Identity.
+	// Defaults to "sub".
+	IdentityClaim string `yaml:"identity_claim"`
+	// VideoGrant is applied to every identity verified against this issuer,
+	// since an external IdP has no notion of LiveKit video grants.
+	VideoGrant auth.VideoGrant `yaml:"video_grant"`
+}
+
+// MTLSVerifierConfig enables resolving participant identity from the client
+// certificate presented to a terminating TLS proxy.
+type MTLSVerifierConfig struct {
+	// IdentityFromCommonName uses the certificate's CommonName as the
+	// participant identity; otherwise identity must be carried in an email
+	// SAN entry.
+	IdentityFromCommonName bool `yaml:"identity_from_common_name"`
+	// VideoGrant is applied to every identity verified via client certificate.
+	VideoGrant auth.VideoGrant `yaml:"video_grant"`
+}
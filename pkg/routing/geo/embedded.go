@@ -0,0 +1,49 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+//go:generate go run ./gen
+
+import (
+	"net"
+)
+
+// EmbeddedResolver resolves a continent straight from a compact set of
+// regional internet registry allocations, without needing an external
+// database on disk. It trades fine-grained per-country accuracy for a
+// resolver that works out of the box; deployments that need country-level
+// precision should configure config.GeoResolverConfig.MaxMindDBPath instead.
+type EmbeddedResolver struct{}
+
+func NewEmbeddedResolver() *EmbeddedResolver {
+	return &EmbeddedResolver{}
+}
+
+func (r *EmbeddedResolver) ResolveContinent(ip net.IP) (Continent, error) {
+	if ip == nil {
+		return ContinentUnknown, ErrNoResolution
+	}
+
+	country, ok := lookupCountry(ip)
+	if !ok {
+		return ContinentUnknown, ErrNoResolution
+	}
+
+	continent, ok := countryToContinent[country]
+	if !ok {
+		return ContinentUnknown, ErrNoResolution
+	}
+	return continent, nil
+}
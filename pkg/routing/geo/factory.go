@@ -0,0 +1,26 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import "github.com/livekit/livekit-server/pkg/config"
+
+// NewResolverFromConfig builds the configured Resolver: a MaxMind database
+// if cfg.MaxMindDBPath is set, otherwise the embedded table.
+func NewResolverFromConfig(cfg config.GeoResolverConfig) (Resolver, error) {
+	if cfg.MaxMindDBPath != "" {
+		return NewMaxMindResolver(cfg.MaxMindDBPath)
+	}
+	return NewEmbeddedResolver(), nil
+}
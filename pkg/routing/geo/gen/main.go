@@ -0,0 +1,145 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command gen writes pkg/routing/geo/country_continent_table.go from the
+// countryToContinent source list below. Run via `go generate ./pkg/routing/geo/...`.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+)
+
+// countryToContinent is the ISO 3166-1 alpha-2 country code to continent
+// code mapping baked into the binary. It changes only when countries are
+// added or reassigned, which is rare enough to regenerate by hand rather
+// than fetch at runtime.
+var countryToContinent = map[string]string{
+	"DZ": "AF", "AO": "AF", "BJ": "AF", "BW": "AF", "BF": "AF", "BI": "AF",
+	"CM": "AF", "CV": "AF", "CF": "AF", "TD": "AF", "KM": "AF", "CG": "AF",
+	"CD": "AF", "CI": "AF", "DJ": "AF", "EG": "AF", "GQ": "AF", "ER": "AF",
+	"SZ": "AF", "ET": "AF", "GA": "AF", "GM": "AF", "GH": "AF", "GN": "AF",
+	"GW": "AF", "KE": "AF", "LS": "AF", "LR": "AF", "LY": "AF", "MG": "AF",
+	"MW": "AF", "ML": "AF", "MR": "AF", "MU": "AF", "MA": "AF", "MZ": "AF",
+	"NA": "AF", "NE": "AF", "NG": "AF", "RW": "AF", "ST": "AF", "SN": "AF",
+	"SC": "AF", "SL": "AF", "SO": "AF", "ZA": "AF", "SS": "AF", "SD": "AF",
+	"TZ": "AF", "TG": "AF", "TN": "AF", "UG": "AF", "ZM": "AF", "ZW": "AF",
+
+	"AQ": "AN",
+
+	"AF": "AS", "AM": "AS", "AZ": "AS", "BH": "AS", "BD": "AS", "BT": "AS",
+	"BN": "AS", "KH": "AS", "CN": "AS", "GE": "AS", "HK": "AS", "IN": "AS",
+	"ID": "AS", "IR": "AS", "IQ": "AS", "IL": "AS", "JP": "AS", "JO": "AS",
+	"KZ": "AS", "KW": "AS", "KG": "AS", "LA": "AS", "LB": "AS", "MO": "AS",
+	"MY": "AS", "MV": "AS", "MN": "AS", "MM": "AS", "NP": "AS", "KP": "AS",
+	"OM": "AS", "PK": "AS", "PS": "AS", "PH": "AS", "QA": "AS", "SA": "AS",
+	"SG": "AS", "KR": "AS", "LK": "AS", "SY": "AS", "TW": "AS", "TJ": "AS",
+	"TH": "AS", "TL": "AS", "TR": "AS", "TM": "AS", "AE": "AS", "UZ": "AS",
+	"VN": "AS", "YE": "AS",
+
+	"AL": "EU", "AD": "EU", "AT": "EU", "BY": "EU", "BE": "EU", "BA": "EU",
+	"BG": "EU", "HR": "EU", "CY": "EU", "CZ": "EU", "DK": "EU", "EE": "EU",
+	"FO": "EU", "FI": "EU", "FR": "EU", "DE": "EU", "GI": "EU", "GR": "EU",
+	"HU": "EU", "IS": "EU", "IE": "EU", "IM": "EU", "IT": "EU", "XK": "EU",
+	"LV": "EU", "LI": "EU", "LT": "EU", "LU": "EU", "MT": "EU", "MD": "EU",
+	"MC": "EU", "ME": "EU", "NL": "EU", "MK": "EU", "NO": "EU", "PL": "EU",
+	"PT": "EU", "RO": "EU", "RU": "EU", "SM": "EU", "RS": "EU", "SK": "EU",
+	"SI": "EU", "ES": "EU", "SE": "EU", "CH": "EU", "UA": "EU", "GB": "EU",
+	"VA": "EU",
+
+	"AI": "NA", "AG": "NA", "AW": "NA", "BS": "NA", "BB": "NA", "BZ": "NA",
+	"BM": "NA", "VG": "NA", "CA": "NA", "KY": "NA", "CR": "NA", "CU": "NA",
+	"CW": "NA", "DM": "NA", "DO": "NA", "SV": "NA", "GL": "NA", "GD": "NA",
+	"GP": "NA", "GT": "NA", "HT": "NA", "HN": "NA", "JM": "NA", "MQ": "NA",
+	"MX": "NA", "MS": "NA", "NI": "NA", "PA": "NA", "PR": "NA", "BL": "NA",
+	"KN": "NA", "LC": "NA", "MF": "NA", "PM": "NA", "VC": "NA", "SX": "NA",
+	"TT": "NA", "TC": "NA", "US": "NA", "VI": "NA",
+
+	"AS": "OC", "AU": "OC", "CK": "OC", "FJ": "OC", "PF": "OC", "GU": "OC",
+	"KI": "OC", "MH": "OC", "FM": "OC", "NR": "OC", "NC": "OC", "NZ": "OC",
+	"NU": "OC", "NF": "OC", "MP": "OC", "PW": "OC", "PG": "OC", "PN": "OC",
+	"WS": "OC", "SB": "OC", "TO": "OC", "TV": "OC", "VU": "OC", "WF": "OC",
+
+	"AR": "SA", "BO": "SA", "BR": "SA", "CL": "SA", "CO": "SA", "EC": "SA",
+	"FK": "SA", "GF": "SA", "GY": "SA", "PY": "SA", "PE": "SA", "SR": "SA",
+	"UY": "SA", "VE": "SA",
+}
+
+func main() {
+	codes := make([]string, 0, len(countryToContinent))
+	for code := range countryToContinent {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var buf bytes.Buffer
+	buf.WriteString(`// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by pkg/routing/geo/gen. DO NOT EDIT.
+
+package geo
+
+var countryToContinent = map[string]Continent{
+`)
+	for _, code := range codes {
+		fmt.Fprintf(&buf, "\t%q: Continent%s,\n", code, continentIdent(countryToContinent[code]))
+	}
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated table: %v", err)
+	}
+
+	if err := os.WriteFile("country_continent_table.go", formatted, 0o644); err != nil {
+		log.Fatalf("writing generated table: %v", err)
+	}
+}
+
+func continentIdent(code string) string {
+	switch code {
+	case "AF":
+		return "Africa"
+	case "AN":
+		return "Antarctica"
+	case "AS":
+		return "Asia"
+	case "EU":
+		return "Europe"
+	case "NA":
+		return "NorthAmerica"
+	case "OC":
+		return "Oceania"
+	case "SA":
+		return "SouthAmerica"
+	default:
+		panic("unknown continent code: " + code)
+	}
+}
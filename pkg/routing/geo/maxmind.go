@@ -0,0 +1,56 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// MaxMindResolver resolves continents from a MaxMind GeoLite2/GeoIP2
+// Country (or City) database, for operators who need real per-country
+// accuracy rather than the coarse embedded table.
+type MaxMindResolver struct {
+	db *maxminddb.Reader
+}
+
+func NewMaxMindResolver(dbPath string) (*MaxMindResolver, error) {
+	db, err := maxminddb.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxMindResolver{db: db}, nil
+}
+
+func (r *MaxMindResolver) Close() error {
+	return r.db.Close()
+}
+
+func (r *MaxMindResolver) ResolveContinent(ip net.IP) (Continent, error) {
+	var record struct {
+		Continent struct {
+			Code string `maxminddb:"code"`
+		} `maxminddb:"continent"`
+	}
+
+	if err := r.db.Lookup(ip, &record); err != nil {
+		return ContinentUnknown, err
+	}
+	if record.Continent.Code == "" {
+		return ContinentUnknown, ErrNoResolution
+	}
+	return Continent(record.Continent.Code), nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var roomNodePlacements = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "livekit",
+	Subsystem: "geo",
+	Name:      "room_node_placements_total",
+	Help:      "Count of room node placements by whether the selected node shared the caller's continent",
+}, []string{"cross_continent"})
+
+// RecordPlacement tags a completed SelectRoomNode decision for operators to
+// track how often placement had to fall back across continents, so they can
+// tell when it's time to add capacity in an under-provisioned region.
+func RecordPlacement(crossContinent bool) {
+	label := "false"
+	if crossContinent {
+		label = "true"
+	}
+	roomNodePlacements.WithLabelValues(label).Inc()
+}
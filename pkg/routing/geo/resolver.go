@@ -0,0 +1,110 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geo resolves a client's remote IP to a continent so room node
+// selection can prefer same-continent SFU nodes before falling back to
+// cross-continent capacity.
+package geo
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Continent is one of the seven continents used to score candidate SFU
+// nodes. It is deliberately coarser than country so a handful of missing
+// entries in the country table doesn't fragment placement decisions.
+type Continent string
+
+const (
+	ContinentAfrica       Continent = "AF"
+	ContinentAntarctica   Continent = "AN"
+	ContinentAsia         Continent = "AS"
+	ContinentEurope       Continent = "EU"
+	ContinentNorthAmerica Continent = "NA"
+	ContinentOceania      Continent = "OC"
+	ContinentSouthAmerica Continent = "SA"
+	ContinentUnknown      Continent = ""
+)
+
+var ErrNoResolution = errors.New("could not resolve remote IP to a continent")
+
+// Resolver maps a remote IP address to the continent it's most likely
+// connecting from.
+type Resolver interface {
+	ResolveContinent(ip net.IP) (Continent, error)
+}
+
+// ClientIP extracts the caller's address from r, honoring X-Forwarded-For
+// and X-Real-IP only when r.RemoteAddr's host is one of trustedProxies.
+// Otherwise (or if no forwarding header is present) r.RemoteAddr is used
+// as-is, so a client can't spoof its region by forging those headers
+// directly against an edge that isn't behind a trusted proxy.
+func ClientIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(host)
+	if remote == nil || !isTrusted(remote, trustedProxies) {
+		return remote
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		// the left-most entry is the original client; everything after it
+		// was appended by proxies we may or may not trust.
+		parts := strings.Split(xff, ",")
+		if ip := net.ParseIP(strings.TrimSpace(parts[0])); ip != nil {
+			return ip
+		}
+	}
+	if xri := r.Header.Get("X-Real-IP"); xri != "" {
+		if ip := net.ParseIP(strings.TrimSpace(xri)); ip != nil {
+			return ip
+		}
+	}
+	return remote
+}
+
+func isTrusted(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies converts the config's CIDR strings into *net.IPNet,
+// treating a bare IP (no "/") as a /32 (or /128 for IPv6).
+func ParseTrustedProxies(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			if strings.Contains(c, ":") {
+				c += "/128"
+			} else {
+				c += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
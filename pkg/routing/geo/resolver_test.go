@@ -0,0 +1,64 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIPUntrustedProxyIgnoresHeaders(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	r := &http.Request{
+		RemoteAddr: "203.0.113.5:1234",
+		Header:     http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+	}
+
+	ip := ClientIP(r, trusted)
+	if ip == nil || ip.String() != "203.0.113.5" {
+		t.Fatalf("expected untrusted RemoteAddr to be used as-is, got %v", ip)
+	}
+}
+
+func TestClientIPTrustedProxyHonorsForwardedFor(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	r := &http.Request{
+		RemoteAddr: "10.1.2.3:1234",
+		Header:     http.Header{"X-Forwarded-For": {"198.51.100.1, 10.1.2.3"}},
+	}
+
+	ip := ClientIP(r, trusted)
+	if ip == nil || ip.String() != "198.51.100.1" {
+		t.Fatalf("expected left-most X-Forwarded-For entry, got %v", ip)
+	}
+}
+
+func TestParseTrustedProxiesBareIP(t *testing.T) {
+	nets, err := ParseTrustedProxies([]string{"203.0.113.5"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	if len(nets) != 1 || nets[0].String() != "203.0.113.5/32" {
+		t.Fatalf("expected bare IP to be normalized to a /32, got %v", nets)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import "net"
+
+// rirBlock is one coarse regional internet registry allocation. This is
+// intentionally a small, representative set of blocks rather than a full
+// delegation list — good enough to pick a same-continent SFU node, not to
+// bill per-country. Operators who need real per-country accuracy should set
+// config.GeoResolverConfig.MaxMindDBPath.
+type rirBlock struct {
+	cidr    *net.IPNet
+	country string
+}
+
+var rirBlocks []rirBlock
+
+func init() {
+	for cidr, country := range rirBlockSource {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic("invalid embedded geo CIDR " + cidr + ": " + err.Error())
+		}
+		rirBlocks = append(rirBlocks, rirBlock{cidr: n, country: country})
+	}
+}
+
+// rirBlockSource maps a small set of well-known regional allocations to a
+// representative country in that region, as a seed for continent-level
+// placement decisions.
+var rirBlockSource = map[string]string{
+	// ARIN (North America)
+	"3.0.0.0/8":    "US",
+	"23.0.0.0/8":   "US",
+	"99.0.0.0/8":   "US",
+	"142.0.0.0/8":  "CA",
+	"198.51.0.0/16": "US",
+
+	// RIPE NCC (Europe)
+	"2.0.0.0/8":   "FR",
+	"5.0.0.0/8":   "GB",
+	"31.0.0.0/8":  "DE",
+	"62.0.0.0/8":  "NL",
+	"77.0.0.0/8":  "RU",
+
+	// APNIC (Asia/Oceania)
+	"1.0.0.0/8":   "AU",
+	"14.0.0.0/8":  "CN",
+	"27.0.0.0/8":  "KR",
+	"58.0.0.0/8":  "JP",
+	"103.0.0.0/8": "SG",
+	"110.0.0.0/8": "IN",
+
+	// LACNIC (South America)
+	"177.0.0.0/8": "BR",
+	"181.0.0.0/8": "AR",
+	"186.0.0.0/8": "CO",
+
+	// AFRINIC (Africa)
+	"41.0.0.0/8":  "ZA",
+	"102.0.0.0/8": "NG",
+	"196.0.0.0/8": "EG",
+}
+
+func lookupCountry(ip net.IP) (string, bool) {
+	for _, b := range rirBlocks {
+		if b.cidr.Contains(ip) {
+			return b.country, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,261 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalv2
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrFragmentTooLarge  = errors.New("fragment exceeds configured envelope byte cap")
+	ErrFragmentOutOfSeq  = errors.New("fragment sequence number out of order")
+	ErrFragmentDuplicate = errors.New("duplicate fragment sequence number")
+)
+
+const (
+	DefaultMaxEnvelopeBytes = 16 * 1024 * 1024
+	DefaultFragmentTTL      = 30 * time.Second
+	DefaultSweepInterval    = 10 * time.Second
+)
+
+// FragmentReassemblerConfig controls how aggressively the reassembler buffers
+// and expires partially received envelopes.
+type FragmentReassemblerConfig struct {
+	// MaxEnvelopeBytes bounds the total size a single (participantID, envelopeID)
+	// reassembly may grow to before it is abandoned.
+	MaxEnvelopeBytes int
+
+	// FragmentTTL is how long a partially received envelope is kept around
+	// waiting for the remaining fragments before it is dropped.
+	FragmentTTL time.Duration
+
+	// SweepInterval is how often stale entries are swept out.
+	SweepInterval time.Duration
+}
+
+func (c *FragmentReassemblerConfig) setDefaults() {
+	if c.MaxEnvelopeBytes <= 0 {
+		c.MaxEnvelopeBytes = DefaultMaxEnvelopeBytes
+	}
+	if c.FragmentTTL <= 0 {
+		c.FragmentTTL = DefaultFragmentTTL
+	}
+	if c.SweepInterval <= 0 {
+		c.SweepInterval = DefaultSweepInterval
+	}
+}
+
+type envelopeKey struct {
+	participantID livekit.ParticipantID
+	envelopeID    uint64
+}
+
+type pendingEnvelope struct {
+	fragments map[uint32][]byte
+	size      int
+	updatedAt time.Time
+
+	// finalSeq is the sequence number of the fragment marked Final, once
+	// seen. It is tracked independently of arrival order since the final
+	// fragment is not guaranteed to arrive last.
+	finalSeq     uint32
+	haveFinalSeq bool
+}
+
+// FragmentReassembler buffers Signalv2WireMessage_Fragment messages keyed by
+// (participantID, envelopeID) and stitches them back into an *livekit.Envelope
+// once every fragment in the sequence has arrived.
+type FragmentReassembler struct {
+	cfg FragmentReassemblerConfig
+
+	mu      sync.Mutex
+	pending map[envelopeKey]*pendingEnvelope
+
+	closeCh chan struct{}
+	closeOnce sync.Once
+}
+
+func NewFragmentReassembler(cfg FragmentReassemblerConfig) *FragmentReassembler {
+	cfg.setDefaults()
+	r := &FragmentReassembler{
+		cfg:     cfg,
+		pending: make(map[envelopeKey]*pendingEnvelope),
+		closeCh: make(chan struct{}),
+	}
+	go r.sweepLoop()
+	return r
+}
+
+// AddFragment ingests a single fragment for participantID. It returns the
+// reassembled envelope once every fragment from sequence number 0 through
+// the one marked Final has been received, in whatever order they arrived,
+// and nil otherwise.
+func (r *FragmentReassembler) AddFragment(
+	participantID livekit.ParticipantID,
+	frag *livekit.Signalv2WireMessage_Fragment,
+) (*livekit.Envelope, error) {
+	f := frag.Fragment
+	key := envelopeKey{participantID: participantID, envelopeID: f.GetEnvelopeId()}
+
+	r.mu.Lock()
+	pe, ok := r.pending[key]
+	if !ok {
+		pe = &pendingEnvelope{fragments: make(map[uint32][]byte)}
+		r.pending[key] = pe
+	}
+
+	if _, dup := pe.fragments[f.GetSequenceNumber()]; dup {
+		r.mu.Unlock()
+		return nil, ErrFragmentDuplicate
+	}
+
+	pe.size += len(f.GetPayload())
+	if pe.size > r.cfg.MaxEnvelopeBytes {
+		delete(r.pending, key)
+		r.mu.Unlock()
+		return nil, ErrFragmentTooLarge
+	}
+
+	pe.fragments[f.GetSequenceNumber()] = f.GetPayload()
+	pe.updatedAt = time.Now()
+
+	if f.GetFinal() {
+		pe.finalSeq = f.GetSequenceNumber()
+		pe.haveFinalSeq = true
+	}
+
+	// completeness must be re-checked on every fragment, not just the one
+	// marked Final, since fragments may arrive out of sequence order.
+	if !pe.haveFinalSeq || uint32(len(pe.fragments)) != pe.finalSeq+1 {
+		r.mu.Unlock()
+		return nil, nil
+	}
+
+	// all sequence numbers [0, final] must be present to stitch the envelope
+	for i := uint32(0); i <= pe.finalSeq; i++ {
+		if _, ok := pe.fragments[i]; !ok {
+			r.mu.Unlock()
+			return nil, nil
+		}
+	}
+
+	delete(r.pending, key)
+	r.mu.Unlock()
+
+	seqs := make([]uint32, 0, len(pe.fragments))
+	for seq := range pe.fragments {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	var buf []byte
+	for _, seq := range seqs {
+		buf = append(buf, pe.fragments[seq]...)
+	}
+
+	envelope := &livekit.Envelope{}
+	if err := proto.Unmarshal(buf, envelope); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+func (r *FragmentReassembler) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closeCh)
+	})
+}
+
+func (r *FragmentReassembler) sweepLoop() {
+	ticker := time.NewTicker(r.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		case <-ticker.C:
+			r.sweep()
+		}
+	}
+}
+
+func (r *FragmentReassembler) sweep() {
+	cutoff := time.Now().Add(-r.cfg.FragmentTTL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, pe := range r.pending {
+		if pe.updatedAt.Before(cutoff) {
+			logger.Debugw(
+				"dropping stale signalv2 envelope fragments",
+				"participant", key.participantID,
+				"envelopeID", key.envelopeID,
+				"fragmentsReceived", len(pe.fragments),
+			)
+			delete(r.pending, key)
+		}
+	}
+}
+
+// SplitEnvelope fragments a server envelope into a sequence of
+// Signalv2WireMessage_Fragment messages whose payloads are no larger than
+// maxBytes each (the message wrapping each payload adds a few bytes). If the
+// marshalled envelope already fits within maxBytes, it is returned unwrapped.
+func SplitEnvelope(envelopeID uint64, envelope *livekit.Envelope, maxBytes int) ([]*livekit.Signalv2WireMessage, error) {
+	buf, err := proto.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buf) <= maxBytes {
+		return []*livekit.Signalv2WireMessage{
+			{
+				Message: &livekit.Signalv2WireMessage_Envelope{
+					Envelope: envelope,
+				},
+			},
+		}, nil
+	}
+
+	var out []*livekit.Signalv2WireMessage
+	for seq := uint32(0); len(buf) > 0; seq++ {
+		chunkSize := maxBytes
+		if chunkSize > len(buf) {
+			chunkSize = len(buf)
+		}
+		chunk := buf[:chunkSize]
+		buf = buf[chunkSize:]
+
+		out = append(out, &livekit.Signalv2WireMessage{
+			Message: &livekit.Signalv2WireMessage_Fragment{
+				Fragment: &livekit.Fragment{
+					EnvelopeId:     envelopeID,
+					SequenceNumber: seq,
+					Final:          len(buf) == 0,
+					Payload:        chunk,
+				},
+			},
+		})
+	}
+	return out, nil
+}
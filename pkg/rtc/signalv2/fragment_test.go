@@ -0,0 +1,105 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalv2
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFragmentReassemblerOutOfOrder(t *testing.T) {
+	envelope := &livekit.Envelope{
+		ServerMessages: []*livekit.Signalv2ServerMessage{
+			{Message: &livekit.Signalv2ServerMessage_Error{Error: &livekit.Signalv2Error{Message: "hello world"}}},
+		},
+	}
+	messages, err := SplitEnvelope(1, envelope, 8)
+	if err != nil {
+		t.Fatalf("SplitEnvelope: %v", err)
+	}
+	if len(messages) < 3 {
+		t.Fatalf("expected envelope to be split into multiple fragments, got %d", len(messages))
+	}
+
+	r := NewFragmentReassembler(FragmentReassemblerConfig{})
+	defer r.Close()
+
+	// feed the fragments in reverse order; the envelope should only come
+	// back once every fragment, including the final one, has been seen.
+	var got *livekit.Envelope
+	for i := len(messages) - 1; i >= 0; i-- {
+		frag, ok := messages[i].GetMessage().(*livekit.Signalv2WireMessage_Fragment)
+		if !ok {
+			t.Fatalf("message %d is not a fragment", i)
+		}
+		envelope, err := r.AddFragment("pID", frag)
+		if err != nil {
+			t.Fatalf("AddFragment: %v", err)
+		}
+		if envelope != nil {
+			got = envelope
+		}
+	}
+
+	if got == nil {
+		t.Fatal("expected a reassembled envelope once all fragments arrived")
+	}
+	if !proto.Equal(got, envelope) {
+		t.Fatalf("reassembled envelope does not match original: got %v, want %v", got, envelope)
+	}
+}
+
+func TestFragmentReassemblerDuplicateSequence(t *testing.T) {
+	r := NewFragmentReassembler(FragmentReassemblerConfig{})
+	defer r.Close()
+
+	frag := &livekit.Signalv2WireMessage_Fragment{
+		Fragment: &livekit.Fragment{EnvelopeId: 1, SequenceNumber: 0, Final: false, Payload: []byte("a")},
+	}
+	if _, err := r.AddFragment("pID", frag); err != nil {
+		t.Fatalf("AddFragment: %v", err)
+	}
+	if _, err := r.AddFragment("pID", frag); err != ErrFragmentDuplicate {
+		t.Fatalf("expected ErrFragmentDuplicate, got %v", err)
+	}
+}
+
+func TestFragmentReassemblerTooLarge(t *testing.T) {
+	r := NewFragmentReassembler(FragmentReassemblerConfig{MaxEnvelopeBytes: 4})
+	defer r.Close()
+
+	frag := &livekit.Signalv2WireMessage_Fragment{
+		Fragment: &livekit.Fragment{EnvelopeId: 1, SequenceNumber: 0, Final: false, Payload: []byte("too big")},
+	}
+	if _, err := r.AddFragment("pID", frag); err != ErrFragmentTooLarge {
+		t.Fatalf("expected ErrFragmentTooLarge, got %v", err)
+	}
+}
+
+func TestSplitEnvelopeFitsInOneMessage(t *testing.T) {
+	envelope := &livekit.Envelope{}
+	messages, err := SplitEnvelope(1, envelope, 4096)
+	if err != nil {
+		t.Fatalf("SplitEnvelope: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("expected a single unwrapped message, got %d", len(messages))
+	}
+	if _, ok := messages[0].GetMessage().(*livekit.Signalv2WireMessage_Envelope); !ok {
+		t.Fatalf("expected an unwrapped envelope message, got %T", messages[0].GetMessage())
+	}
+}
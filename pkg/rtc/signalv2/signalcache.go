@@ -0,0 +1,257 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalv2
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// DefaultRingBufferSize is how many of the most recent server messages a
+// SignalCache retains per participant for replay on reconnect.
+const DefaultRingBufferSize = 256
+
+// DefaultParticipantTTL bounds how long an InMemorySignalCache entry survives
+// after its last activity, so a participant that disconnects for good
+// doesn't pin its ring buffer and acked-message set in memory forever.
+const DefaultParticipantTTL = 10 * time.Minute
+
+// DefaultParticipantSweepInterval is how often InMemorySignalCache scans for
+// entries past DefaultParticipantTTL.
+const DefaultParticipantSweepInterval = time.Minute
+
+// SignalCacheKey identifies the per-participant signal cache entry. It is
+// deliberately a value type so it can be used as a map key both in-process
+// and as a Redis key component.
+type SignalCacheKey struct {
+	RoomName            livekit.RoomName
+	ParticipantIdentity livekit.ParticipantIdentity
+	ParticipantID       livekit.ParticipantID
+}
+
+// SignalCache stamps outgoing Signalv2ServerMessages with a strictly
+// increasing per-participant message_id, retains a bounded window of the
+// most recently sent ones for replay, and de-duplicates client messages that
+// have already been acknowledged so a PATCH retried after a dropped response
+// does not get applied twice.
+type SignalCache interface {
+	// StampServerMessage assigns the next message_id to msg and records it
+	// in the participant's ring buffer.
+	StampServerMessage(key SignalCacheKey, msg *livekit.Signalv2ServerMessage) error
+
+	// ReplaySince returns the cached server messages with message_id greater
+	// than lastProcessedServerMessageID, in order. If that ID has already
+	// aged out of the ring buffer, ok is false and the caller must fall back
+	// to a full re-SDP.
+	ReplaySince(key SignalCacheKey, lastProcessedServerMessageID uint64) (msgs []*livekit.Signalv2ServerMessage, ok bool, err error)
+
+	// IsDuplicateClientMessage reports whether clientMessageID has already
+	// been acknowledged for this participant. It does not itself record
+	// clientMessageID as seen — call AckClientMessage once the message has
+	// actually been applied, so a message that turned out not to be
+	// duplicate isn't wrongly dropped on retry if applying it fails.
+	IsDuplicateClientMessage(key SignalCacheKey, clientMessageID uint64) (bool, error)
+
+	// AckClientMessage records clientMessageID as acknowledged for this
+	// participant, so a later retry of the same message is recognized by
+	// IsDuplicateClientMessage and dropped.
+	AckClientMessage(key SignalCacheKey, clientMessageID uint64) error
+
+	// Close releases any resources (timers, connections) held by the cache.
+	Close()
+}
+
+type ringEntry struct {
+	messageID uint64
+	msg       *livekit.Signalv2ServerMessage
+}
+
+type participantCache struct {
+	mu sync.Mutex
+
+	nextMessageID uint64
+	ring          []ringEntry
+	ringCap       int
+
+	ackedClientMessageIDs map[uint64]struct{}
+	lastActivity          time.Time
+}
+
+func newParticipantCache(ringCap int) *participantCache {
+	return &participantCache{
+		ringCap:               ringCap,
+		ackedClientMessageIDs: make(map[uint64]struct{}),
+		lastActivity:          time.Now(),
+	}
+}
+
+func (p *participantCache) stamp(msg *livekit.Signalv2ServerMessage) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastActivity = time.Now()
+	p.nextMessageID++
+	id := p.nextMessageID
+	msg.MessageId = id
+
+	p.ring = append(p.ring, ringEntry{messageID: id, msg: msg})
+	if len(p.ring) > p.ringCap {
+		p.ring = p.ring[len(p.ring)-p.ringCap:]
+	}
+	return id
+}
+
+func (p *participantCache) replaySince(lastProcessedServerMessageID uint64) ([]*livekit.Signalv2ServerMessage, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastActivity = time.Now()
+
+	if len(p.ring) == 0 {
+		return nil, lastProcessedServerMessageID == 0
+	}
+
+	oldest := p.ring[0].messageID
+	if lastProcessedServerMessageID != 0 && lastProcessedServerMessageID < oldest-1 {
+		// the requested cursor has already aged out of the ring buffer
+		return nil, false
+	}
+
+	var out []*livekit.Signalv2ServerMessage
+	for _, entry := range p.ring {
+		if entry.messageID > lastProcessedServerMessageID {
+			out = append(out, entry.msg)
+		}
+	}
+	return out, true
+}
+
+func (p *participantCache) isDuplicateClientMessage(clientMessageID uint64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastActivity = time.Now()
+	_, dup := p.ackedClientMessageIDs[clientMessageID]
+	return dup
+}
+
+func (p *participantCache) ackClientMessage(clientMessageID uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastActivity = time.Now()
+	p.ackedClientMessageIDs[clientMessageID] = struct{}{}
+}
+
+func (p *participantCache) idleSince(cutoff time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastActivity.Before(cutoff)
+}
+
+// InMemorySignalCache is a single-node SignalCache suitable for deployments
+// where PATCH requests for a participant always land on the node holding
+// that participant's media session. For multi-node routing where a PATCH can
+// land on a different edge node than the one relaying server messages, use
+// NewRedisSignalCache instead.
+type InMemorySignalCache struct {
+	ringCap int
+
+	mu           sync.Mutex
+	participants map[SignalCacheKey]*participantCache
+
+	ttl       time.Duration
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func NewInMemorySignalCache(ringBufferSize int) *InMemorySignalCache {
+	if ringBufferSize <= 0 {
+		ringBufferSize = DefaultRingBufferSize
+	}
+	c := &InMemorySignalCache{
+		ringCap:      ringBufferSize,
+		participants: make(map[SignalCacheKey]*participantCache),
+		ttl:          DefaultParticipantTTL,
+		closeCh:      make(chan struct{}),
+	}
+	go c.sweepLoop()
+	return c
+}
+
+func (c *InMemorySignalCache) get(key SignalCacheKey) *participantCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pc, ok := c.participants[key]
+	if !ok {
+		pc = newParticipantCache(c.ringCap)
+		c.participants[key] = pc
+	}
+	return pc
+}
+
+func (c *InMemorySignalCache) StampServerMessage(key SignalCacheKey, msg *livekit.Signalv2ServerMessage) error {
+	c.get(key).stamp(msg)
+	return nil
+}
+
+func (c *InMemorySignalCache) ReplaySince(key SignalCacheKey, lastProcessedServerMessageID uint64) ([]*livekit.Signalv2ServerMessage, bool, error) {
+	msgs, ok := c.get(key).replaySince(lastProcessedServerMessageID)
+	return msgs, ok, nil
+}
+
+func (c *InMemorySignalCache) IsDuplicateClientMessage(key SignalCacheKey, clientMessageID uint64) (bool, error) {
+	return c.get(key).isDuplicateClientMessage(clientMessageID), nil
+}
+
+func (c *InMemorySignalCache) AckClientMessage(key SignalCacheKey, clientMessageID uint64) error {
+	c.get(key).ackClientMessage(clientMessageID)
+	return nil
+}
+
+func (c *InMemorySignalCache) sweepLoop() {
+	ticker := time.NewTicker(DefaultParticipantSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closeCh:
+			return
+		case <-ticker.C:
+			c.sweep()
+		}
+	}
+}
+
+func (c *InMemorySignalCache) sweep() {
+	cutoff := time.Now().Add(-c.ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, pc := range c.participants {
+		if pc.idleSince(cutoff) {
+			delete(c.participants, key)
+		}
+	}
+}
+
+func (c *InMemorySignalCache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closeCh)
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalv2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// signalCacheTTL bounds how long a participant's ring buffer and dedup set
+// survive in Redis after the last write, so a permanently disconnected
+// participant doesn't leak keys forever.
+const signalCacheTTL = 5 * time.Minute
+
+// RedisSignalCache is a SignalCache backed by Redis so that a PATCH landing
+// on a different edge node than the one relaying server messages can still
+// read the authoritative next message_id and replay window.
+type RedisSignalCache struct {
+	rc      redis.UniversalClient
+	ringCap int
+}
+
+func NewRedisSignalCache(rc redis.UniversalClient, ringBufferSize int) *RedisSignalCache {
+	if ringBufferSize <= 0 {
+		ringBufferSize = DefaultRingBufferSize
+	}
+	return &RedisSignalCache{rc: rc, ringCap: ringBufferSize}
+}
+
+func (c *RedisSignalCache) counterKey(key SignalCacheKey) string {
+	return fmt.Sprintf("signalv2:cache:%s:%s:%s:counter", key.RoomName, key.ParticipantIdentity, key.ParticipantID)
+}
+
+func (c *RedisSignalCache) ringKey(key SignalCacheKey) string {
+	return fmt.Sprintf("signalv2:cache:%s:%s:%s:ring", key.RoomName, key.ParticipantIdentity, key.ParticipantID)
+}
+
+func (c *RedisSignalCache) dedupKey(key SignalCacheKey) string {
+	return fmt.Sprintf("signalv2:cache:%s:%s:%s:dedup", key.RoomName, key.ParticipantIdentity, key.ParticipantID)
+}
+
+func (c *RedisSignalCache) StampServerMessage(key SignalCacheKey, msg *livekit.Signalv2ServerMessage) error {
+	ctx := context.Background()
+
+	id, err := c.rc.Incr(ctx, c.counterKey(key)).Result()
+	if err != nil {
+		return err
+	}
+	msg.MessageId = uint64(id)
+
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ringKey := c.ringKey(key)
+	pipe := c.rc.TxPipeline()
+	pipe.RPush(ctx, ringKey, payload)
+	pipe.LTrim(ctx, ringKey, int64(-c.ringCap), -1)
+	pipe.Expire(ctx, ringKey, signalCacheTTL)
+	pipe.Expire(ctx, c.counterKey(key), signalCacheTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *RedisSignalCache) ReplaySince(key SignalCacheKey, lastProcessedServerMessageID uint64) ([]*livekit.Signalv2ServerMessage, bool, error) {
+	ctx := context.Background()
+
+	raw, err := c.rc.LRange(ctx, c.ringKey(key), 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(raw) == 0 {
+		return nil, lastProcessedServerMessageID == 0, nil
+	}
+
+	msgs := make([]*livekit.Signalv2ServerMessage, 0, len(raw))
+	for _, payload := range raw {
+		msg := &livekit.Signalv2ServerMessage{}
+		if err := proto.Unmarshal([]byte(payload), msg); err != nil {
+			return nil, false, err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	oldest := msgs[0].MessageId
+	if lastProcessedServerMessageID != 0 && lastProcessedServerMessageID < oldest-1 {
+		return nil, false, nil
+	}
+
+	out := msgs[:0:0]
+	for _, msg := range msgs {
+		if msg.MessageId > lastProcessedServerMessageID {
+			out = append(out, msg)
+		}
+	}
+	return out, true, nil
+}
+
+func (c *RedisSignalCache) IsDuplicateClientMessage(key SignalCacheKey, clientMessageID uint64) (bool, error) {
+	ctx := context.Background()
+
+	member := fmt.Sprintf("%d", clientMessageID)
+	return c.rc.SIsMember(ctx, c.dedupKey(key), member).Result()
+}
+
+func (c *RedisSignalCache) AckClientMessage(key SignalCacheKey, clientMessageID uint64) error {
+	ctx := context.Background()
+
+	dedupKey := c.dedupKey(key)
+	member := fmt.Sprintf("%d", clientMessageID)
+
+	if _, err := c.rc.SAdd(ctx, dedupKey, member).Result(); err != nil {
+		return err
+	}
+	return c.rc.Expire(ctx, dedupKey, signalCacheTTL).Err()
+}
+
+func (c *RedisSignalCache) Close() {}
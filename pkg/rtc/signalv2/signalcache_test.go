@@ -0,0 +1,110 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalv2
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func testCacheKey() SignalCacheKey {
+	return SignalCacheKey{RoomName: "room", ParticipantIdentity: "identity", ParticipantID: "pID"}
+}
+
+func TestInMemorySignalCacheReplaySince(t *testing.T) {
+	c := NewInMemorySignalCache(4)
+	defer c.Close()
+
+	key := testCacheKey()
+	for i := 0; i < 4; i++ {
+		msg := &livekit.Signalv2ServerMessage{}
+		if err := c.StampServerMessage(key, msg); err != nil {
+			t.Fatalf("StampServerMessage: %v", err)
+		}
+	}
+
+	msgs, ok, err := c.ReplaySince(key, 2)
+	if err != nil {
+		t.Fatalf("ReplaySince: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected replay to succeed within the ring buffer window")
+	}
+	if len(msgs) != 2 || msgs[0].MessageId != 3 || msgs[1].MessageId != 4 {
+		t.Fatalf("unexpected replay result: %+v", msgs)
+	}
+}
+
+func TestInMemorySignalCacheReplaySinceAgedOut(t *testing.T) {
+	c := NewInMemorySignalCache(2)
+	defer c.Close()
+
+	key := testCacheKey()
+	for i := 0; i < 4; i++ {
+		if err := c.StampServerMessage(key, &livekit.Signalv2ServerMessage{}); err != nil {
+			t.Fatalf("StampServerMessage: %v", err)
+		}
+	}
+
+	// the ring buffer only holds the last 2 messages (IDs 3 and 4); a cursor
+	// from before that window must report ok=false so the caller falls back
+	// to a full re-SDP instead of silently skipping message 2.
+	_, ok, err := c.ReplaySince(key, 1)
+	if err != nil {
+		t.Fatalf("ReplaySince: %v", err)
+	}
+	if ok {
+		t.Fatal("expected replay to report aged-out cursor as not ok")
+	}
+}
+
+func TestInMemorySignalCacheDedup(t *testing.T) {
+	c := NewInMemorySignalCache(0)
+	defer c.Close()
+
+	key := testCacheKey()
+
+	dup, err := c.IsDuplicateClientMessage(key, 1)
+	if err != nil {
+		t.Fatalf("IsDuplicateClientMessage: %v", err)
+	}
+	if dup {
+		t.Fatal("message 1 should not be a duplicate before it is acked")
+	}
+
+	// a second check before acking must still report not-duplicate: the
+	// check itself must not record the message as seen, only AckClientMessage
+	// may, so a relay failure doesn't lose the retry.
+	dup, err = c.IsDuplicateClientMessage(key, 1)
+	if err != nil {
+		t.Fatalf("IsDuplicateClientMessage: %v", err)
+	}
+	if dup {
+		t.Fatal("checking a message must not itself mark it as acked")
+	}
+
+	if err := c.AckClientMessage(key, 1); err != nil {
+		t.Fatalf("AckClientMessage: %v", err)
+	}
+
+	dup, err = c.IsDuplicateClientMessage(key, 1)
+	if err != nil {
+		t.Fatalf("IsDuplicateClientMessage: %v", err)
+	}
+	if !dup {
+		t.Fatal("message 1 should be a duplicate once acked")
+	}
+}
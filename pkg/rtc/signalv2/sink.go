@@ -0,0 +1,144 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalv2
+
+import (
+	"sync"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// ServerMessageSink is a push destination for Signalv2ServerMessages
+// produced outside the request/response cycle of a single PATCH or connect
+// call — trickled ICE candidates, room state deltas, participant list
+// updates. A streaming transport (SSE, websocket) registers a sink for a
+// participant and drains it for the lifetime of the connection.
+type ServerMessageSink interface {
+	// Push enqueues msg for delivery. It must not block for long; a slow
+	// consumer should apply backpressure by closing the sink rather than
+	// stalling the producer.
+	Push(msg *livekit.Signalv2ServerMessage) error
+
+	// Close releases the sink. Push after Close is a no-op.
+	Close()
+}
+
+// ChannelServerMessageSink is a ServerMessageSink backed by a buffered Go
+// channel, suitable for a single HTTP handler goroutine to range over.
+type ChannelServerMessageSink struct {
+	mu     sync.Mutex
+	ch     chan *livekit.Signalv2ServerMessage
+	closed bool
+}
+
+func NewChannelServerMessageSink(bufferSize int) *ChannelServerMessageSink {
+	if bufferSize <= 0 {
+		bufferSize = 32
+	}
+	return &ChannelServerMessageSink{
+		ch: make(chan *livekit.Signalv2ServerMessage, bufferSize),
+	}
+}
+
+func (s *ChannelServerMessageSink) Push(msg *livekit.Signalv2ServerMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	select {
+	case s.ch <- msg:
+	default:
+		// a saturated channel means the consumer has fallen behind; drop
+		// the oldest rather than block the producer indefinitely.
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- msg:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *ChannelServerMessageSink) Messages() <-chan *livekit.Signalv2ServerMessage {
+	return s.ch
+}
+
+func (s *ChannelServerMessageSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+}
+
+// ServerMessageRegistry tracks the live ServerMessageSink for each
+// participant so any code relaying a server message (a PATCH response, an
+// out-of-band psrpc push) can also fan it out to a streaming transport
+// that's currently attached.
+type ServerMessageRegistry struct {
+	mu    sync.Mutex
+	sinks map[SignalCacheKey]ServerMessageSink
+}
+
+func NewServerMessageRegistry() *ServerMessageRegistry {
+	return &ServerMessageRegistry{sinks: make(map[SignalCacheKey]ServerMessageSink)}
+}
+
+// Register attaches sink for key, replacing and closing any sink already
+// registered for the same participant (e.g. a stale SSE connection that
+// hasn't noticed its client is gone yet).
+func (r *ServerMessageRegistry) Register(key SignalCacheKey, sink ServerMessageSink) {
+	r.mu.Lock()
+	old := r.sinks[key]
+	r.sinks[key] = sink
+	r.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+}
+
+// Unregister removes sink for key if it is still the one registered there.
+func (r *ServerMessageRegistry) Unregister(key SignalCacheKey, sink ServerMessageSink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sinks[key] == sink {
+		delete(r.sinks, key)
+	}
+}
+
+// Push forwards msg to key's registered sink, if any, and reports whether
+// one was attached.
+func (r *ServerMessageRegistry) Push(key SignalCacheKey, msg *livekit.Signalv2ServerMessage) bool {
+	r.mu.Lock()
+	sink := r.sinks[key]
+	r.mu.Unlock()
+
+	if sink == nil {
+		return false
+	}
+	_ = sink.Push(msg)
+	return true
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/redis/go-redis/v9"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/geo"
+	"github.com/livekit/livekit-server/pkg/rtc/signalv2"
+	"github.com/livekit/protocol/rpc"
+)
+
+// NewRTCv2ServiceFromConfig wires up the request verifier chain, geo
+// resolver, signal cache, and room allocator from their config structs and
+// constructs a RTCv2Service from the result, so configuring
+// request_verifiers/geo_resolver/redis on the server is enough to turn these
+// features on without every call site repeating this wiring by hand.
+//
+// SIGNALLING-V2-TODO: requestVerifiers and geoResolverConfig are accepted
+// directly rather than read off cfg because the top-level server Config
+// (which is expected to embed them as `request_verifiers` and
+// `geo_resolver`, per RequestVerifiersConfig/GeoResolverConfig's doc
+// comments) lives outside this package slice.
+func NewRTCv2ServiceFromConfig(
+	cfg *config.Config,
+	requestVerifiers config.RequestVerifiersConfig,
+	geoResolverConfig config.GeoResolverConfig,
+	nodeCandidates NodeCandidateProvider,
+	redisClient redis.UniversalClient,
+	router routing.MessageRouter,
+	topicFormatter rpc.TopicFormatter,
+	signalv2ParticipantClient rpc.TypedSignalv2ParticipantClient,
+) (*RTCv2Service, error) {
+	verifiers, err := BuildRequestVerifierChain(requestVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	geoResolver, err := geo.NewResolverFromConfig(geoResolverConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var signalCache signalv2.SignalCache
+	if redisClient != nil {
+		signalCache = signalv2.NewRedisSignalCache(redisClient, 0)
+	} else {
+		signalCache = signalv2.NewInMemorySignalCache(0)
+	}
+
+	return NewRTCv2Service(
+		cfg,
+		NewStaticRoomAllocator(nodeCandidates),
+		router,
+		topicFormatter,
+		signalv2ParticipantClient,
+		signalCache,
+		verifiers,
+		geoResolver,
+		geoResolverConfig,
+	), nil
+}
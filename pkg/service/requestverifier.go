@@ -0,0 +1,130 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/protocol/auth"
+)
+
+// ErrNoVerifierApplicable is returned by a RequestVerifier when the request
+// carries none of the credentials it knows how to check (e.g. no bearer
+// token for a JWT verifier), so the chain should try the next verifier
+// rather than treat it as an authentication failure.
+var ErrNoVerifierApplicable = errors.New("request carries no credentials this verifier understands")
+
+// VerifiedIdentity is what a RequestVerifier resolves an inbound request to.
+type VerifiedIdentity struct {
+	Grants auth.ClaimGrants
+}
+
+// RequestVerifier authenticates a Signalv2 HTTP request against a trust
+// anchor other than a LiveKit-minted access token, e.g. an external IdP or a
+// client certificate presented at a terminating zero-trust proxy.
+type RequestVerifier interface {
+	Verify(ctx context.Context, r *http.Request) (VerifiedIdentity, error)
+}
+
+// RequestVerifierChain runs an ordered list of RequestVerifiers and
+// short-circuits on the first one that successfully resolves an identity.
+type RequestVerifierChain []RequestVerifier
+
+func (c RequestVerifierChain) Verify(ctx context.Context, r *http.Request) (VerifiedIdentity, error) {
+	var lastErr error = ErrNoVerifierApplicable
+	for _, v := range c {
+		identity, err := v.Verify(ctx, r)
+		if err == nil {
+			return identity, nil
+		}
+		if !errors.Is(err, ErrNoVerifierApplicable) {
+			lastErr = err
+		}
+	}
+	return VerifiedIdentity{}, lastErr
+}
+
+// BuildRequestVerifierChain constructs the RequestVerifierChain described by
+// cfg, in the order JWT/OIDC issuers then mTLS, so the first configured
+// verifier that successfully verifies the request wins.
+func BuildRequestVerifierChain(cfg config.RequestVerifiersConfig) (RequestVerifierChain, error) {
+	var chain RequestVerifierChain
+	for _, issuerCfg := range cfg.JWTOIDC {
+		v, err := NewJWTOIDCVerifier(issuerCfg)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, v)
+	}
+	if cfg.MTLS != nil {
+		chain = append(chain, NewMTLSVerifier(*cfg.MTLS))
+	}
+	return chain, nil
+}
+
+// Close stops every verifier in the chain that holds background resources
+// (e.g. a JWTOIDCVerifier's JWKS refresh goroutine), so a RTCv2Service that
+// is torn down does not leak them.
+func (c RequestVerifierChain) Close() {
+	for _, v := range c {
+		if closer, ok := v.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+}
+
+// resolveGrants returns the claims already established for r by the normal
+// API-key based auth middleware, falling back to s.verifiers when those are
+// absent. This lets Signalv2 endpoints sit behind an external IdP or mTLS
+// proxy without requiring a LiveKit access token for every request.
+func (s *RTCv2Service) resolveGrants(r *http.Request) (*auth.ClaimGrants, error) {
+	if claims := GetGrants(r.Context()); claims != nil {
+		return claims, nil
+	}
+	if len(s.verifiers) == 0 {
+		return nil, nil
+	}
+
+	identity, err := s.verifiers.Verify(r.Context(), r)
+	if err != nil {
+		return nil, err
+	}
+	return &identity.Grants, nil
+}
+
+// resolveConnectIdentity returns the identity s.verifiers resolves for a
+// connect request, so a request authenticated by an external IdP or mTLS
+// proxy can establish a Signalv2 session without a LiveKit access token ever
+// being minted. It returns (nil, nil) when the request already carries a
+// LiveKit-token-derived identity (the normal auth middleware already set
+// GetGrants) or no verifiers are configured; either way the caller should
+// fall back to its normal token-based validation.
+func (s *RTCv2Service) resolveConnectIdentity(r *http.Request) (*VerifiedIdentity, error) {
+	if GetGrants(r.Context()) != nil || len(s.verifiers) == 0 {
+		return nil, nil
+	}
+
+	identity, err := s.verifiers.Verify(r.Context(), r)
+	if err != nil {
+		if errors.Is(err, ErrNoVerifierApplicable) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
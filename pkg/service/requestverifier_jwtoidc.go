@@ -0,0 +1,154 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/logger"
+)
+
+const defaultJWKSRefreshInterval = 15 * time.Minute
+
+// JWTOIDCVerifier validates `Authorization: Bearer` tokens issued by an
+// external OIDC issuer, mapping approved claims onto auth.ClaimGrants so
+// operators can front LiveKit with their own IdP instead of minting LiveKit
+// access tokens.
+type JWTOIDCVerifier struct {
+	cfg config.JWTOIDCVerifierConfig
+
+	mu      sync.RWMutex
+	keySet  jwk.Set
+	stopped chan struct{}
+}
+
+func NewJWTOIDCVerifier(cfg config.JWTOIDCVerifierConfig) (*JWTOIDCVerifier, error) {
+	if cfg.JWKSURL == "" {
+		cfg.JWKSURL = strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/jwks.json"
+	}
+	if cfg.IdentityClaim == "" {
+		cfg.IdentityClaim = "sub"
+	}
+	if cfg.JWKSRefreshInterval <= 0 {
+		cfg.JWKSRefreshInterval = defaultJWKSRefreshInterval
+	}
+
+	v := &JWTOIDCVerifier{
+		cfg:     cfg,
+		stopped: make(chan struct{}),
+	}
+	if err := v.refreshKeySet(context.Background()); err != nil {
+		return nil, fmt.Errorf("fetching initial JWKS from %s: %w", cfg.JWKSURL, err)
+	}
+
+	go v.refreshLoop()
+	return v, nil
+}
+
+func (v *JWTOIDCVerifier) refreshLoop() {
+	ticker := time.NewTicker(v.cfg.JWKSRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-v.stopped:
+			return
+		case <-ticker.C:
+			if err := v.refreshKeySet(context.Background()); err != nil {
+				logger.Errorw("could not refresh JWKS", err, "issuer", v.cfg.Issuer)
+			}
+		}
+	}
+}
+
+func (v *JWTOIDCVerifier) refreshKeySet(ctx context.Context) error {
+	keySet, err := jwk.Fetch(ctx, v.cfg.JWKSURL)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.keySet = keySet
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *JWTOIDCVerifier) Close() {
+	close(v.stopped)
+}
+
+func (v *JWTOIDCVerifier) Verify(ctx context.Context, r *http.Request) (VerifiedIdentity, error) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return VerifiedIdentity{}, ErrNoVerifierApplicable
+	}
+	rawToken := strings.TrimPrefix(authHeader, "Bearer ")
+
+	v.mu.RLock()
+	keySet := v.keySet
+	v.mu.RUnlock()
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := keySet.LookupKeyID(kid)
+		if !ok {
+			return nil, fmt.Errorf("no JWKS key for kid %q", kid)
+		}
+		var pubKey rsa.PublicKey
+		if err := key.Raw(&pubKey); err != nil {
+			return nil, err
+		}
+		return &pubKey, nil
+	},
+		jwt.WithIssuer(v.cfg.Issuer),
+		jwt.WithAudience(v.cfg.Audience),
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+	)
+	if err != nil {
+		return VerifiedIdentity{}, fmt.Errorf("verifying external JWT: %w", err)
+	}
+
+	identity, ok := claims[v.cfg.IdentityClaim].(string)
+	if !ok || identity == "" {
+		return VerifiedIdentity{}, fmt.Errorf("token missing identity claim %q", v.cfg.IdentityClaim)
+	}
+
+	grants := auth.ClaimGrants{
+		Identity: identity,
+		Video:    &v.cfg.VideoGrant,
+	}
+	if attrs, ok := claims["attributes"].(map[string]interface{}); ok {
+		grants.Attributes = make(map[string]string, len(attrs))
+		for k, val := range attrs {
+			if s, ok := val.(string); ok {
+				grants.Attributes[k] = s
+			}
+		}
+	}
+
+	return VerifiedIdentity{Grants: grants}, nil
+}
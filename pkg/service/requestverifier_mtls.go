@@ -0,0 +1,59 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/protocol/auth"
+)
+
+// MTLSVerifier resolves participant identity from the client certificate
+// presented to a terminating TLS proxy, for deployments where the trust
+// anchor is a private CA rather than a LiveKit API key.
+type MTLSVerifier struct {
+	cfg config.MTLSVerifierConfig
+}
+
+func NewMTLSVerifier(cfg config.MTLSVerifierConfig) *MTLSVerifier {
+	return &MTLSVerifier{cfg: cfg}
+}
+
+func (v *MTLSVerifier) Verify(ctx context.Context, r *http.Request) (VerifiedIdentity, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return VerifiedIdentity{}, ErrNoVerifierApplicable
+	}
+	cert := r.TLS.PeerCertificates[0]
+
+	var identity string
+	if v.cfg.IdentityFromCommonName {
+		identity = cert.Subject.CommonName
+	} else if len(cert.EmailAddresses) > 0 {
+		identity = cert.EmailAddresses[0]
+	}
+	if identity == "" {
+		return VerifiedIdentity{}, fmt.Errorf("client certificate carries no usable identity")
+	}
+
+	return VerifiedIdentity{
+		Grants: auth.ClaimGrants{
+			Identity: identity,
+			Video:    &v.cfg.VideoGrant,
+		},
+	}, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/routing/geo"
+	"github.com/livekit/protocol/livekit"
+)
+
+// ErrNoNodeCandidates is returned when a StaticRoomAllocator has no node to
+// place a room on because NodeCandidateProvider returned an empty set. A
+// candidate's Load never excludes it, so a fully loaded node is still chosen
+// if it scores best.
+var ErrNoNodeCandidates = errors.New("no node candidates available")
+
+// RoomAllocator picks the SFU node a room's media session runs on.
+//
+// SIGNALLING-V2-TODO: the room-creation side of this interface
+// (CreateRoom/ValidateCreateRoom) lives with the rest of the routing stack
+// outside this package; RTCv2Service only depends on SelectRoomNode.
+type RoomAllocator interface {
+	// SelectRoomNode assigns roomName to an SFU node. If nodeID is
+	// non-empty, that specific node is used (e.g. an existing room being
+	// rejoined); otherwise a node is chosen among available candidates,
+	// preferring one in region when known.
+	SelectRoomNode(ctx context.Context, roomName livekit.RoomName, nodeID livekit.NodeID, region geo.Continent) error
+}
+
+// NodeCandidate is one SFU node a RoomAllocator can place a room on.
+type NodeCandidate struct {
+	ID     livekit.NodeID
+	Region geo.Continent
+	// RTT is the last-measured round trip time from this node to the
+	// region making the request, used to break ties between candidates that
+	// are equally matched (both same-continent or both not) on region.
+	RTT time.Duration
+	// Load is the node's current normalized load, 0 (idle) to 1 (full).
+	Load float32
+}
+
+// NodeCandidateProvider supplies the set of SFU nodes a StaticRoomAllocator
+// may place a new room on. The concrete implementation (backed by the node
+// registry routing.MessageRouter maintains) lives outside this package.
+type NodeCandidateProvider interface {
+	Candidates(ctx context.Context, roomName livekit.RoomName) ([]NodeCandidate, error)
+	AssignNode(ctx context.Context, roomName livekit.RoomName, nodeID livekit.NodeID) error
+}
+
+// StaticRoomAllocator implements RoomAllocator's geo-aware node selection:
+// prefer a same-continent candidate, break ties by lowest RTT, and break
+// remaining ties by lowest load.
+type StaticRoomAllocator struct {
+	nodes NodeCandidateProvider
+}
+
+func NewStaticRoomAllocator(nodes NodeCandidateProvider) *StaticRoomAllocator {
+	return &StaticRoomAllocator{nodes: nodes}
+}
+
+func (a *StaticRoomAllocator) SelectRoomNode(ctx context.Context, roomName livekit.RoomName, nodeID livekit.NodeID, region geo.Continent) error {
+	if nodeID != "" {
+		return a.nodes.AssignNode(ctx, roomName, nodeID)
+	}
+
+	candidates, err := a.nodes.Candidates(ctx, roomName)
+	if err != nil {
+		return err
+	}
+
+	best, crossContinent, err := selectBestCandidate(candidates, region)
+	if err != nil {
+		return err
+	}
+	geo.RecordPlacement(crossContinent)
+
+	return a.nodes.AssignNode(ctx, roomName, best.ID)
+}
+
+// selectBestCandidate scores candidates same-continent-first, then lowest
+// RTT, then lowest load, and reports whether the winner is outside region
+// (or region is unknown, in which case every candidate counts as
+// cross-continent since no same-continent match could be confirmed).
+// It returns ErrNoNodeCandidates if candidates is empty rather than silently
+// picking the zero NodeCandidate{}.
+func selectBestCandidate(candidates []NodeCandidate, region geo.Continent) (NodeCandidate, bool, error) {
+	if len(candidates) == 0 {
+		return NodeCandidate{}, false, ErrNoNodeCandidates
+	}
+
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if betterCandidate(c, best, region) {
+			best = c
+		}
+	}
+	return best, region == geo.ContinentUnknown || best.Region != region, nil
+}
+
+func betterCandidate(c, best NodeCandidate, region geo.Continent) bool {
+	cSameRegion := region != geo.ContinentUnknown && c.Region == region
+	bestSameRegion := region != geo.ContinentUnknown && best.Region == region
+	if cSameRegion != bestSameRegion {
+		return cSameRegion
+	}
+	if c.RTT != best.RTT {
+		return c.RTT < best.RTT
+	}
+	return c.Load < best.Load
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/routing/geo"
+)
+
+func TestSelectBestCandidateNoCandidates(t *testing.T) {
+	_, _, err := selectBestCandidate(nil, geo.ContinentUnknown)
+	if !errors.Is(err, ErrNoNodeCandidates) {
+		t.Fatalf("expected ErrNoNodeCandidates, got %v", err)
+	}
+}
+
+func TestSelectBestCandidatePrefersSameRegion(t *testing.T) {
+	candidates := []NodeCandidate{
+		{ID: "far", Region: geo.ContinentEurope, RTT: 10 * time.Millisecond},
+		{ID: "near", Region: geo.ContinentNorthAmerica, RTT: 200 * time.Millisecond},
+	}
+
+	best, crossContinent, err := selectBestCandidate(candidates, geo.ContinentNorthAmerica)
+	if err != nil {
+		t.Fatalf("selectBestCandidate: %v", err)
+	}
+	if best.ID != "near" {
+		t.Fatalf("expected same-region candidate to win despite higher RTT, got %s", best.ID)
+	}
+	if crossContinent {
+		t.Fatal("expected a same-region match to not count as cross-continent")
+	}
+}
+
+func TestSelectBestCandidateBreaksTiesByRTTThenLoad(t *testing.T) {
+	candidates := []NodeCandidate{
+		{ID: "a", Region: geo.ContinentEurope, RTT: 10 * time.Millisecond, Load: 0.9},
+		{ID: "b", Region: geo.ContinentEurope, RTT: 10 * time.Millisecond, Load: 0.1},
+		// lowest load but higher RTT: RTT is compared before load, so it loses
+		{ID: "c", Region: geo.ContinentEurope, RTT: 20 * time.Millisecond, Load: 0.0},
+	}
+
+	best, _, err := selectBestCandidate(candidates, geo.ContinentUnknown)
+	if err != nil {
+		t.Fatalf("selectBestCandidate: %v", err)
+	}
+	if best.ID != "b" {
+		t.Fatalf("expected lower-load candidate to win an RTT tie, got %s", best.ID)
+	}
+}
+
+func TestSelectBestCandidateUnknownRegionIsAlwaysCrossContinent(t *testing.T) {
+	candidates := []NodeCandidate{{ID: "a", Region: geo.ContinentEurope}}
+
+	_, crossContinent, err := selectBestCandidate(candidates, geo.ContinentUnknown)
+	if err != nil {
+		t.Fatalf("selectBestCandidate: %v", err)
+	}
+	if !crossContinent {
+		t.Fatal("expected an unresolved region to always count as cross-continent")
+	}
+}
@@ -19,11 +19,15 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/geo"
 	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/signalv2"
+	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/rpc"
@@ -50,6 +54,13 @@ type RTCv2Service struct {
 
 	topicFormatter            rpc.TopicFormatter
 	signalv2ParticipantClient rpc.TypedSignalv2ParticipantClient
+
+	signalCache    signalv2.SignalCache
+	serverMessages *signalv2.ServerMessageRegistry
+	verifiers      RequestVerifierChain
+
+	geoResolver    geo.Resolver
+	trustedProxies []*net.IPNet
 }
 
 func NewRTCv2Service(
@@ -58,19 +69,60 @@ func NewRTCv2Service(
 	router routing.MessageRouter,
 	topicFormatter rpc.TopicFormatter,
 	signalv2ParticipantClient rpc.TypedSignalv2ParticipantClient,
+	signalCache signalv2.SignalCache,
+	verifiers RequestVerifierChain,
+	geoResolver geo.Resolver,
+	geoResolverConfig config.GeoResolverConfig,
 ) *RTCv2Service {
+	trustedProxies, err := geo.ParseTrustedProxies(geoResolverConfig.TrustedProxies)
+	if err != nil {
+		logger.Errorw("could not parse geo resolver trusted proxies", err)
+	}
+
 	return &RTCv2Service{
 		limits:                    config.Limit,
 		router:                    router,
 		roomAllocator:             roomAllocator,
 		topicFormatter:            topicFormatter,
 		signalv2ParticipantClient: signalv2ParticipantClient,
+		signalCache:               signalCache,
+		serverMessages:            signalv2.NewServerMessageRegistry(),
+		verifiers:                 verifiers,
+		geoResolver:               geoResolver,
+		trustedProxies:            trustedProxies,
+	}
+}
+
+// preferredRegion resolves r's remote IP (honoring trusted proxy forwarding
+// headers) to a continent RoomAllocator.SelectRoomNode can use to prefer a
+// same-continent SFU node over one farther away. It is passed as its own
+// region argument, never as the nodeID, which continues to mean "no node
+// pinned" when empty.
+func (s *RTCv2Service) preferredRegion(r *http.Request) geo.Continent {
+	if s.geoResolver == nil {
+		return geo.ContinentUnknown
+	}
+
+	ip := geo.ClientIP(r, s.trustedProxies)
+	continent, err := s.geoResolver.ResolveContinent(ip)
+	if err != nil {
+		logger.Debugw("could not resolve continent for signalv2 request", "error", err, "ip", ip)
+		return geo.ContinentUnknown
 	}
+	return continent
 }
 
 func (s *RTCv2Service) SetupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST "+cRTCv2Path, s.handlePost)
 	mux.HandleFunc("PATCH "+cRTCv2ParticipantIDPath, s.handleParticipantPatch)
+	mux.HandleFunc("GET "+cRTCv2WSPath, s.handleWS)
+}
+
+// Close releases resources held on behalf of this service, such as the
+// verifier chain's JWKS refresh goroutines. It must be called as part of
+// server shutdown.
+func (s *RTCv2Service) Close() {
+	s.verifiers.Close()
 }
 
 func (s *RTCv2Service) validateInternal(
@@ -78,6 +130,14 @@ func (s *RTCv2Service) validateInternal(
 	r *http.Request,
 	connectRequest *livekit.ConnectRequest,
 ) (livekit.RoomName, livekit.ParticipantIdentity, *rpc.RelaySignalv2ConnectRequest, int, error) {
+	identity, err := s.resolveConnectIdentity(r)
+	if err != nil {
+		return "", "", nil, http.StatusUnauthorized, err
+	}
+	if identity != nil {
+		return s.connectWithVerifiedIdentity(r, connectRequest, identity.Grants)
+	}
+
 	params := ValidateConnectRequestParams{
 		metadata:   connectRequest.Metadata,
 		attributes: connectRequest.ParticipantAttributes,
@@ -113,12 +173,53 @@ func (s *RTCv2Service) validateInternal(
 		err
 }
 
+// connectWithVerifiedIdentity builds a connect request directly from an
+// identity s.verifiers resolved, bypassing ValidateConnectRequest's
+// LiveKit-token parsing entirely. This is what lets an operator front
+// LiveKit with an external IdP or mTLS proxy without ever minting a LiveKit
+// access token for the connect itself.
+func (s *RTCv2Service) connectWithVerifiedIdentity(
+	r *http.Request,
+	connectRequest *livekit.ConnectRequest,
+	grants auth.ClaimGrants,
+) (livekit.RoomName, livekit.ParticipantIdentity, *rpc.RelaySignalv2ConnectRequest, int, error) {
+	if grants.Identity == "" {
+		return "", "", nil, http.StatusUnauthorized, ErrIdentityEmpty
+	}
+	if grants.Video == nil || grants.Video.Room == "" {
+		return "", "", nil, http.StatusUnauthorized, ErrNoRoomName
+	}
+	roomName := livekit.RoomName(grants.Video.Room)
+
+	grantsJson, err := json.Marshal(grants)
+	if err != nil {
+		return "", "", nil, http.StatusInternalServerError, err
+	}
+
+	AugmentClientInfo(connectRequest.ClientInfo, r)
+
+	return roomName,
+		livekit.ParticipantIdentity(grants.Identity),
+		&rpc.RelaySignalv2ConnectRequest{
+			GrantsJson:     string(grantsJson),
+			CreateRoom:     &livekit.CreateRoomRequest{Name: string(roomName)},
+			ConnectRequest: connectRequest,
+		},
+		http.StatusOK,
+		nil
+}
+
 func (s *RTCv2Service) handlePost(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get("Content-type") != "application/x-protobuf" {
 		HandleErrorJson(w, r, http.StatusBadRequest, fmt.Errorf("unsupported content-type: %s", r.Header.Get("Content-type")))
 		return
 	}
 
+	if acceptsEventStream(r) {
+		s.handlePostSSE(w, r)
+		return
+	}
+
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		HandleErrorJson(w, r, http.StatusBadRequest, fmt.Errorf("could not read request body: %w", err))
@@ -147,7 +248,11 @@ func (s *RTCv2Service) handlePost(w http.ResponseWriter, r *http.Request) {
 					return
 				}
 
-				if err := s.roomAllocator.SelectRoomNode(r.Context(), roomName, ""); err != nil {
+				// nodeID is left empty, same as baseline: it means "let the
+				// allocator pick", it is not where the region hint goes. The
+				// allocator is expected to use region to prefer a
+				// same-continent node before falling back to RTT and load.
+				if err := s.roomAllocator.SelectRoomNode(r.Context(), roomName, "", s.preferredRegion(r)); err != nil {
 					HandleErrorJson(w, r, http.StatusInternalServerError, err)
 					return
 				}
@@ -158,17 +263,25 @@ func (s *RTCv2Service) handlePost(w http.ResponseWriter, r *http.Request) {
 					return
 				}
 
-				// SIGNALLING-V2-TODO: this needs to be in signal cache and get messageId
+				serverMessage := &livekit.Signalv2ServerMessage{
+					Message: &livekit.Signalv2ServerMessage_ConnectResponse{
+						ConnectResponse: resp.ConnectResponse,
+					},
+				}
+				cacheKey := signalv2.SignalCacheKey{
+					RoomName:            roomName,
+					ParticipantIdentity: participantIdentity,
+					ParticipantID:       livekit.ParticipantID(resp.ConnectResponse.Participant.Sid),
+				}
+				if err := s.signalCache.StampServerMessage(cacheKey, serverMessage); err != nil {
+					HandleErrorJson(w, r, http.StatusInternalServerError, err)
+					return
+				}
+
 				wireMessage := &livekit.Signalv2WireMessage{
 					Message: &livekit.Signalv2WireMessage_Envelope{
 						Envelope: &livekit.Envelope{
-							ServerMessages: []*livekit.Signalv2ServerMessage{
-								&livekit.Signalv2ServerMessage{
-									Message: &livekit.Signalv2ServerMessage_ConnectResponse{
-										ConnectResponse: resp.ConnectResponse,
-									},
-								},
-							},
+							ServerMessages: []*livekit.Signalv2ServerMessage{serverMessage},
 						},
 					},
 				}
@@ -215,7 +328,11 @@ func (s *RTCv2Service) handleParticipantPatch(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	claims := GetGrants(r.Context())
+	claims, err := s.resolveGrants(r)
+	if err != nil {
+		HandleErrorJson(w, r, http.StatusUnauthorized, err)
+		return
+	}
 	if claims == nil || claims.Video == nil {
 		HandleErrorJson(w, r, http.StatusUnauthorized, rtc.ErrPermissionDenied)
 		return
@@ -256,6 +373,44 @@ func (s *RTCv2Service) handleParticipantPatch(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	cacheKey := signalv2.SignalCacheKey{
+		RoomName:            roomName,
+		ParticipantIdentity: participantIdentity,
+		ParticipantID:       pID,
+	}
+
+	var replayed []*livekit.Signalv2ServerMessage
+	var forwardedClientMessageIDs []uint64
+	if envelope := wireMessage.GetEnvelope(); envelope != nil {
+		if lastProcessed := envelope.GetLastProcessedServerMessageId(); lastProcessed > 0 {
+			var ok bool
+			replayed, ok, err = s.signalCache.ReplaySince(cacheKey, lastProcessed)
+			if err != nil {
+				HandleErrorJson(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			if !ok {
+				// lastProcessed has already aged out of the ring buffer;
+				// proceed as a full re-SDP with nothing to replay.
+				replayed = nil
+			}
+		}
+
+		envelope.ClientMessages = s.dropDuplicateClientMessages(cacheKey, envelope.GetClientMessages())
+		if len(envelope.ClientMessages) == 0 {
+			if len(replayed) == 0 {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			s.writeReplayedMessages(w, r, roomName, participantIdentity, pID, replayed)
+			return
+		}
+
+		for _, clientMessage := range envelope.ClientMessages {
+			forwardedClientMessageIDs = append(forwardedClientMessageIDs, clientMessage.GetMessageId())
+		}
+	}
+
 	res, err := s.signalv2ParticipantClient.RelaySignalv2Participant(
 		r.Context(),
 		s.topicFormatter.ParticipantTopic(r.Context(), roomName, participantIdentity),
@@ -284,6 +439,15 @@ func (s *RTCv2Service) handleParticipantPatch(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// only record these as acknowledged now that the relay has actually
+	// succeeded, so a client retrying after a failed relay (e.g. a 500) gets
+	// the message applied instead of silently dropped as a duplicate.
+	for _, messageID := range forwardedClientMessageIDs {
+		if err := s.signalCache.AckClientMessage(cacheKey, messageID); err != nil {
+			logger.Errorw("could not ack signalv2 client message", err)
+		}
+	}
+
 	logger.Debugw(
 		"participant response",
 		"room", roomName,
@@ -292,6 +456,22 @@ func (s *RTCv2Service) handleParticipantPatch(w http.ResponseWriter, r *http.Req
 		"participantResponse", logger.Proto(res),
 	)
 
+	for _, serverMessage := range res.WireMessage.GetEnvelope().GetServerMessages() {
+		if err := s.signalCache.StampServerMessage(cacheKey, serverMessage); err != nil {
+			HandleErrorJson(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		s.serverMessages.Push(cacheKey, serverMessage)
+	}
+
+	// any messages the client missed while disconnected are delivered ahead
+	// of this PATCH's own response, in the order they were originally sent.
+	if len(replayed) > 0 {
+		if resEnvelope := res.WireMessage.GetEnvelope(); resEnvelope != nil {
+			resEnvelope.ServerMessages = append(replayed, resEnvelope.ServerMessages...)
+		}
+	}
+
 	marshalled, err := proto.Marshal(res.WireMessage)
 	if err != nil {
 		HandleErrorJson(w, r, http.StatusInternalServerError, err)
@@ -303,3 +483,60 @@ func (s *RTCv2Service) handleParticipantPatch(w http.ResponseWriter, r *http.Req
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// writeReplayedMessages responds to a reconnect PATCH directly from the
+// signal cache, without forwarding to the participant's media session, since
+// the client is only catching up on server messages it may have missed.
+func (s *RTCv2Service) writeReplayedMessages(
+	w http.ResponseWriter,
+	r *http.Request,
+	roomName livekit.RoomName,
+	participantIdentity livekit.ParticipantIdentity,
+	pID livekit.ParticipantID,
+	replayed []*livekit.Signalv2ServerMessage,
+) {
+	wireMessage := &livekit.Signalv2WireMessage{
+		Message: &livekit.Signalv2WireMessage_Envelope{
+			Envelope: &livekit.Envelope{ServerMessages: replayed},
+		},
+	}
+	marshalled, err := proto.Marshal(wireMessage)
+	if err != nil {
+		HandleErrorJson(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	logger.Debugw(
+		"replayed cached signalv2 server messages",
+		"room", roomName,
+		"participant", participantIdentity,
+		"pID", pID,
+		"count", len(replayed),
+	)
+
+	w.Header().Add("Content-type", "application/x-protobuf")
+	w.Write(marshalled)
+	w.WriteHeader(http.StatusOK)
+}
+
+// dropDuplicateClientMessages filters out client messages whose message_id
+// has already been acknowledged for this participant, so a PATCH retried
+// after a dropped response is not applied twice.
+func (s *RTCv2Service) dropDuplicateClientMessages(
+	cacheKey signalv2.SignalCacheKey,
+	clientMessages []*livekit.Signalv2ClientMessage,
+) []*livekit.Signalv2ClientMessage {
+	filtered := clientMessages[:0:0]
+	for _, clientMessage := range clientMessages {
+		dup, err := s.signalCache.IsDuplicateClientMessage(cacheKey, clientMessage.GetMessageId())
+		if err != nil {
+			logger.Errorw("could not check signalv2 client message cache", err)
+			filtered = append(filtered, clientMessage)
+			continue
+		}
+		if !dup {
+			filtered = append(filtered, clientMessage)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/rtc/signalv2"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+func acceptsEventStream(r *http.Request) bool {
+	return r.Header.Get("Accept") == "text/event-stream"
+}
+
+// handlePostSSE is the streaming counterpart of handlePost: instead of
+// buffering a single ConnectResponse and closing, it holds the connection
+// open and flushes every Signalv2ServerMessage produced for the participant
+// as an SSE frame, so trickled ICE candidates and room state deltas don't
+// require a client to poll PATCH.
+func (s *RTCv2Service) handlePostSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		HandleErrorJson(w, r, http.StatusInternalServerError, fmt.Errorf("streaming unsupported by response writer"))
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		HandleErrorJson(w, r, http.StatusBadRequest, fmt.Errorf("could not read request body: %w", err))
+		return
+	}
+
+	wireMessage := &livekit.Signalv2WireMessage{}
+	if err := proto.Unmarshal(body, wireMessage); err != nil {
+		HandleErrorJson(w, r, http.StatusBadRequest, fmt.Errorf("could not unmarshal request: %w", err))
+		return
+	}
+
+	envelope := wireMessage.GetEnvelope()
+	if envelope == nil {
+		HandleErrorJson(w, r, http.StatusBadRequest, errFragmentsInHTTP)
+		return
+	}
+
+	var connectRequest *livekit.ConnectRequest
+	for _, innerMsg := range envelope.GetClientMessages() {
+		if cr, ok := innerMsg.GetMessage().(*livekit.Signalv2ClientMessage_ConnectRequest); ok {
+			connectRequest = cr.ConnectRequest
+			break
+		}
+	}
+	if connectRequest == nil {
+		HandleErrorJson(w, r, http.StatusBadRequest, fmt.Errorf("%w, first SSE connect must carry a ConnectRequest", errUnknownMessageType))
+		return
+	}
+
+	roomName, participantIdentity, rscr, code, err := s.validateInternal(logger.GetLogger(), r, connectRequest)
+	if err != nil {
+		HandleErrorJson(w, r, code, err)
+		return
+	}
+
+	// nodeID stays empty here too; the region hint is a separate argument,
+	// not a stand-in for pinning to a specific node.
+	if err := s.roomAllocator.SelectRoomNode(r.Context(), roomName, "", s.preferredRegion(r)); err != nil {
+		HandleErrorJson(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp, err := s.router.HandleParticipantConnectRequest(r.Context(), roomName, participantIdentity, rscr)
+	if err != nil {
+		HandleErrorJson(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	pID := livekit.ParticipantID(resp.ConnectResponse.Participant.Sid)
+	cacheKey := signalv2.SignalCacheKey{
+		RoomName:            roomName,
+		ParticipantIdentity: participantIdentity,
+		ParticipantID:       pID,
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	connectMsg := &livekit.Signalv2ServerMessage{
+		Message: &livekit.Signalv2ServerMessage_ConnectResponse{ConnectResponse: resp.ConnectResponse},
+	}
+	if err := s.signalCache.StampServerMessage(cacheKey, connectMsg); err != nil {
+		logger.Errorw("could not stamp signalv2 connect response", err)
+		return
+	}
+	if !writeSSEFrame(w, flusher, connectMsg) {
+		return
+	}
+
+	// Last-Event-ID lets a client that dropped the stream resume from the
+	// cached message window instead of reconnecting from scratch.
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		if lastID, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+			if replayed, ok, err := s.signalCache.ReplaySince(cacheKey, lastID); err == nil && ok {
+				for _, msg := range replayed {
+					if !writeSSEFrame(w, flusher, msg) {
+						return
+					}
+				}
+			}
+		}
+	}
+
+	sink := signalv2.NewChannelServerMessageSink(32)
+	s.serverMessages.Register(cacheKey, sink)
+	defer func() {
+		s.serverMessages.Unregister(cacheKey, sink)
+		sink.Close()
+	}()
+
+	// sink delivers messages already stamped by whoever pushed them to the
+	// registry (handleParticipantPatch); re-stamping
+	// here would hand out a second message_id for the same message and break
+	// both monotonicity and the PATCH response the client also received it on.
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sink.Messages():
+			if !ok {
+				return
+			}
+			if !writeSSEFrame(w, flusher, msg) {
+				return
+			}
+		}
+	}
+}
+
+// writeSSEFrame writes msg as a single SSE event: the assigned message_id as
+// the event id and the base64-encoded marshalled protobuf as data. It
+// reports whether the write succeeded.
+func writeSSEFrame(w http.ResponseWriter, flusher http.Flusher, msg *livekit.Signalv2ServerMessage) bool {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		logger.Errorw("could not marshal signalv2 server message for SSE", err)
+		return false
+	}
+
+	if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", msg.GetMessageId(), base64.StdEncoding.EncodeToString(payload)); err != nil {
+		return false
+	}
+	flusher.Flush()
+	return true
+}
@@ -0,0 +1,343 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/rtc/signalv2"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/rpc"
+)
+
+const cRTCv2WSPath = "/rtc/v2/ws"
+
+// maxWSServerMessageBytes keeps egress frames comfortably under typical path
+// MTUs so browsers don't have to reassemble at the TCP layer either.
+const maxWSServerMessageBytes = 1200
+
+var wsEnvelopeIDCounter uint64
+
+// wsEnvelopeID hands out a per-process monotonic ID used to group fragments
+// of a single outgoing envelope; it is unrelated to the signal cache's
+// per-participant message_id.
+func wsEnvelopeID() uint64 {
+	return atomic.AddUint64(&wsEnvelopeIDCounter, 1)
+}
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsConnWriter serializes writes to a signalv2 websocket connection. Once a
+// participant is connected, both handleWS's read loop (replying to a PATCH
+// equivalent sent over the socket) and drainWSServerMessages (pushing
+// out-of-band server messages) write frames on the same *websocket.Conn, and
+// gorilla/websocket does not allow concurrent writers.
+type wsConnWriter struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (cw *wsConnWriter) write(messageType int, data []byte) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.conn.WriteMessage(messageType, data)
+}
+
+// handleWS upgrades a /rtc/v2/ws request and speaks the same Signalv2WireMessage
+// protobuf as handlePost/handleParticipantPatch, except that it also accepts
+// and emits Fragment messages so a single persistent connection can carry a
+// ConnectRequest (or any envelope) too large to fit in one frame.
+func (s *RTCv2Service) handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Errorw("could not upgrade signalv2 websocket", err)
+		return
+	}
+	defer conn.Close()
+	cw := &wsConnWriter{conn: conn}
+
+	reassembler := signalv2.NewFragmentReassembler(signalv2.FragmentReassemblerConfig{})
+	defer reassembler.Close()
+
+	// the first frame on the connection must be a ConnectRequest envelope;
+	// subsequent frames are routed as participant patches once pID is known.
+	var (
+		roomName            livekit.RoomName
+		participantIdentity livekit.ParticipantIdentity
+		pID                 livekit.ParticipantID
+	)
+
+	for {
+		msgType, body, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Infow("signalv2 websocket closed unexpectedly", "error", err)
+			}
+			return
+		}
+		if msgType != websocket.BinaryMessage {
+			continue
+		}
+
+		wireMessage := &livekit.Signalv2WireMessage{}
+		if err := proto.Unmarshal(body, wireMessage); err != nil {
+			s.writeWSError(cw, fmt.Errorf("could not unmarshal request: %w", err))
+			continue
+		}
+
+		var envelope *livekit.Envelope
+		switch msg := wireMessage.GetMessage().(type) {
+		case *livekit.Signalv2WireMessage_Envelope:
+			envelope = msg.Envelope
+
+		case *livekit.Signalv2WireMessage_Fragment:
+			envelope, err = reassembler.AddFragment(pID, msg)
+			if err != nil {
+				s.writeWSError(cw, err)
+				continue
+			}
+			if envelope == nil {
+				// still waiting on the rest of the fragments
+				continue
+			}
+
+		default:
+			s.writeWSError(cw, fmt.Errorf("%w, message: %T", errUnknownMessageType, msg))
+			continue
+		}
+
+		if roomName == "" {
+			var sink *signalv2.ChannelServerMessageSink
+			roomName, participantIdentity, pID, sink, err = s.handleWSConnect(cw, r, envelope)
+			if err != nil {
+				return
+			}
+
+			cacheKey := signalv2.SignalCacheKey{
+				RoomName:            roomName,
+				ParticipantIdentity: participantIdentity,
+				ParticipantID:       pID,
+			}
+			s.serverMessages.Register(cacheKey, sink)
+			defer func() {
+				s.serverMessages.Unregister(cacheKey, sink)
+				sink.Close()
+			}()
+			go s.drainWSServerMessages(cw, sink)
+
+			continue
+		}
+
+		if err := s.handleWSParticipantEnvelope(cw, r, roomName, participantIdentity, pID, envelope); err != nil {
+			return
+		}
+	}
+}
+
+// handleWSConnect processes the initial envelope on a signalv2 websocket,
+// which is expected to carry exactly one ConnectRequest client message, and
+// returns the identifiers needed to route subsequent frames along with the
+// sink handleWS should register so out-of-band server messages reach this
+// connection for the rest of its lifetime.
+func (s *RTCv2Service) handleWSConnect(
+	cw *wsConnWriter,
+	r *http.Request,
+	envelope *livekit.Envelope,
+) (livekit.RoomName, livekit.ParticipantIdentity, livekit.ParticipantID, *signalv2.ChannelServerMessageSink, error) {
+	for _, innerMsg := range envelope.GetClientMessages() {
+		connectRequest, ok := innerMsg.GetMessage().(*livekit.Signalv2ClientMessage_ConnectRequest)
+		if !ok {
+			continue
+		}
+
+		roomName, participantIdentity, rscr, _, err := s.validateInternal(logger.GetLogger(), r, connectRequest.ConnectRequest)
+		if err != nil {
+			s.writeWSError(cw, err)
+			return "", "", "", nil, err
+		}
+
+		// nodeID stays empty; region is a distinct argument the allocator
+		// uses to prefer a same-continent node.
+		if err := s.roomAllocator.SelectRoomNode(r.Context(), roomName, "", s.preferredRegion(r)); err != nil {
+			s.writeWSError(cw, err)
+			return "", "", "", nil, err
+		}
+
+		resp, err := s.router.HandleParticipantConnectRequest(r.Context(), roomName, participantIdentity, rscr)
+		if err != nil {
+			s.writeWSError(cw, err)
+			return "", "", "", nil, err
+		}
+
+		pID := livekit.ParticipantID(resp.ConnectResponse.Participant.Sid)
+		cacheKey := signalv2.SignalCacheKey{
+			RoomName:            roomName,
+			ParticipantIdentity: participantIdentity,
+			ParticipantID:       pID,
+		}
+
+		if err := s.writeWSEnvelope(cw, cacheKey, &livekit.Envelope{
+			ServerMessages: []*livekit.Signalv2ServerMessage{
+				{
+					Message: &livekit.Signalv2ServerMessage_ConnectResponse{
+						ConnectResponse: resp.ConnectResponse,
+					},
+				},
+			},
+		}); err != nil {
+			return "", "", "", nil, err
+		}
+
+		return roomName, participantIdentity, pID, signalv2.NewChannelServerMessageSink(32), nil
+	}
+
+	err := fmt.Errorf("first signalv2 websocket envelope must contain a ConnectRequest")
+	s.writeWSError(cw, err)
+	return "", "", "", nil, err
+}
+
+// handleWSParticipantEnvelope relays a post-connect envelope to the same
+// signalv2ParticipantClient used by handleParticipantPatch, so both
+// transports route a participant's messages the same way. Unlike
+// handleParticipantPatch, it does not drop already-acknowledged client
+// messages, record acks, or replay cached server messages.
+func (s *RTCv2Service) handleWSParticipantEnvelope(
+	cw *wsConnWriter,
+	r *http.Request,
+	roomName livekit.RoomName,
+	participantIdentity livekit.ParticipantIdentity,
+	pID livekit.ParticipantID,
+	envelope *livekit.Envelope,
+) error {
+	wireMessage := &livekit.Signalv2WireMessage{
+		Message: &livekit.Signalv2WireMessage_Envelope{Envelope: envelope},
+	}
+
+	res, err := s.signalv2ParticipantClient.RelaySignalv2Participant(
+		r.Context(),
+		s.topicFormatter.ParticipantTopic(r.Context(), roomName, participantIdentity),
+		&rpc.RelaySignalv2ParticipantRequest{
+			Room:                string(roomName),
+			ParticipantIdentity: string(participantIdentity),
+			ParticipantId:       string(pID),
+			WireMessage:         wireMessage,
+		},
+	)
+	if err != nil {
+		s.writeWSError(cw, err)
+		return nil
+	}
+
+	if resEnvelope := res.WireMessage.GetEnvelope(); resEnvelope != nil {
+		cacheKey := signalv2.SignalCacheKey{
+			RoomName:            roomName,
+			ParticipantIdentity: participantIdentity,
+			ParticipantID:       pID,
+		}
+		return s.writeWSEnvelope(cw, cacheKey, resEnvelope)
+	}
+	return nil
+}
+
+// drainWSServerMessages writes out-of-band server messages (trickled ICE,
+// other-participant/room deltas pushed via s.serverMessages.Push by
+// handleParticipantPatch) to cw for the rest
+// of the connection's lifetime, the same way handlePostSSE drains its sink.
+// Without this, a websocket client only ever receives a server message in
+// direct reply to its own frame, contradicting the point of keeping one
+// persistent connection open for the full session.
+func (s *RTCv2Service) drainWSServerMessages(cw *wsConnWriter, sink *signalv2.ChannelServerMessageSink) {
+	for msg := range sink.Messages() {
+		// msg was already stamped and pushed to the registry by whoever sent
+		// it; restamping here would hand out a second message_id for the
+		// same message.
+		if err := s.writeWSFrames(cw, &livekit.Envelope{
+			ServerMessages: []*livekit.Signalv2ServerMessage{msg},
+		}); err != nil {
+			return
+		}
+	}
+}
+
+// writeWSEnvelope stamps every server message in envelope through the same
+// SignalCache used by the PATCH and SSE transports — so a client resuming
+// over PATCH/SSE after this connection drops can replay what it sent over
+// the websocket too — then writes it. It does not push them to
+// s.serverMessages: the sink registered under cacheKey is this connection's
+// own, and drainWSServerMessages would write each message a second time.
+func (s *RTCv2Service) writeWSEnvelope(cw *wsConnWriter, cacheKey signalv2.SignalCacheKey, envelope *livekit.Envelope) error {
+	for _, serverMessage := range envelope.GetServerMessages() {
+		if err := s.signalCache.StampServerMessage(cacheKey, serverMessage); err != nil {
+			s.writeWSError(cw, err)
+			return nil
+		}
+	}
+
+	return s.writeWSFrames(cw, envelope)
+}
+
+// writeWSFrames fragments envelope above maxWSServerMessageBytes so a large
+// ServerMessages batch doesn't exceed typical MTU in one frame, and writes
+// the result to cw.
+func (s *RTCv2Service) writeWSFrames(cw *wsConnWriter, envelope *livekit.Envelope) error {
+	messages, err := signalv2.SplitEnvelope(wsEnvelopeID(), envelope, maxWSServerMessageBytes)
+	if err != nil {
+		s.writeWSError(cw, err)
+		return nil
+	}
+
+	for _, wireMessage := range messages {
+		marshalled, err := proto.Marshal(wireMessage)
+		if err != nil {
+			return err
+		}
+		if err := cw.write(websocket.BinaryMessage, marshalled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RTCv2Service) writeWSError(cw *wsConnWriter, err error) {
+	logger.Errorw("signalv2 websocket error", err)
+	marshalled, marshalErr := proto.Marshal(&livekit.Signalv2WireMessage{
+		Message: &livekit.Signalv2WireMessage_Envelope{
+			Envelope: &livekit.Envelope{
+				ServerMessages: []*livekit.Signalv2ServerMessage{
+					{
+						Message: &livekit.Signalv2ServerMessage_Error{
+							Error: &livekit.Signalv2Error{Message: err.Error()},
+						},
+					},
+				},
+			},
+		},
+	})
+	if marshalErr != nil {
+		return
+	}
+	_ = cw.write(websocket.BinaryMessage, marshalled)
+}